@@ -0,0 +1,68 @@
+package memory
+
+import "container/list"
+
+// Allocation records a single lease handed out by a FloatPool or
+// Float64Pool: the region of the backing buffer it occupies, and the
+// address of its first element, used to find it again on Return.
+type Allocation struct {
+	Offset int
+	Size   int
+	Addr   uintptr
+}
+
+// AllocationList tracks live allocations in offset order, so Next can find
+// the next gap large enough for a new allocation and Remove can free one by
+// address. The zero value is not usable; use NewAllocationList.
+type AllocationList struct {
+	list *list.List
+}
+
+// NewAllocationList creates an empty AllocationList.
+func NewAllocationList() AllocationList {
+	return AllocationList{list: list.New()}
+}
+
+// Next searches forward from start (the element at which to resume
+// scanning, or nil to scan from the front) for the next offset at or after
+// pos with at least length free elements before the following allocation,
+// returning that offset and the element to insert the new allocation
+// before. bufLen is not consulted: the returned offset may lie at or past
+// the end of the caller's buffer, which the caller then grows to fit.
+func (al AllocationList) Next(start *list.Element, pos, bufLen, length int) (int, *list.Element) {
+	for e := start; e != nil; e = e.Next() {
+		a := e.Value.(*Allocation)
+		if a.Offset < pos {
+			continue
+		}
+		if a.Offset-pos >= length {
+			return pos, e
+		}
+		pos = a.Offset + a.Size + 1
+	}
+	return pos, nil
+}
+
+// InsertBefore records a new allocation immediately before ele (or at the
+// end of the list if ele is nil), returning the inserted element.
+func (al AllocationList) InsertBefore(a *Allocation, ele *list.Element) *list.Element {
+	if ele == nil {
+		return al.list.PushBack(a)
+	}
+	return al.list.InsertBefore(a, ele)
+}
+
+// Remove finds and removes the allocation with the given address, returning
+// the removed record and the element that followed it (nil if addr wasn't
+// found, or it was the last element).
+func (al AllocationList) Remove(addr uintptr) (*Allocation, *list.Element) {
+	for e := al.list.Front(); e != nil; e = e.Next() {
+		a := e.Value.(*Allocation)
+		if a.Addr == addr {
+			next := e.Next()
+			al.list.Remove(e)
+			return a, next
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,68 @@
+package memory
+
+import "container/list"
+
+// allocBook tracks offset-based leases out of a growing arena. FloatPool
+// and Float64Pool share an identical Make/Return bookkeeping algorithm over
+// different element types, so that part is factored out here; each pool
+// still owns and grows its own backing buffer.
+type allocBook struct {
+	allocations AllocationList
+	pos         int
+	start       *list.Element
+}
+
+func newAllocBook() allocBook {
+	return allocBook{allocations: NewAllocationList()}
+}
+
+// reserve finds the next gap of at least length starting from the search
+// cursor, and the buffer length (bufLen doubled as many times as needed)
+// required to hold it. Callers grow their own backing buffer to grownLen
+// (element types differ between pools) before calling record.
+func (b *allocBook) reserve(bufLen, length int) (offset int, ele *list.Element, grownLen int) {
+	offset, ele = b.allocations.Next(b.start, b.pos, bufLen, length)
+
+	grownLen = bufLen
+	for offset+length >= grownLen {
+		grownLen *= 2
+	}
+
+	return offset, ele, grownLen
+}
+
+// record registers the allocation reserved by a prior call to reserve,
+// advancing the search cursor past it.
+func (b *allocBook) record(offset, length int, addr uintptr, ele *list.Element) {
+	ele = b.allocations.InsertBefore(&Allocation{
+		Offset: offset,
+		Size:   length,
+		Addr:   addr,
+	}, ele)
+
+	b.pos = offset + length + 1
+	b.start = ele
+}
+
+// release removes the allocation at addr, if any, updating the search
+// cursor so the freed offset is found on the next reserve. ok is false if
+// addr wasn't a live allocation recorded by record.
+func (b *allocBook) release(addr uintptr) (removed *Allocation, ok bool) {
+	removed, next := b.allocations.Remove(addr)
+	if removed == nil {
+		return nil, false
+	}
+
+	if removed.Offset < b.pos {
+		b.pos = removed.Offset
+		b.start = next
+	}
+
+	return removed, true
+}
+
+// reset clears the book back to empty, e.g. after shrinking the backing
+// buffer back down to its initial size.
+func (b *allocBook) reset() {
+	*b = newAllocBook()
+}
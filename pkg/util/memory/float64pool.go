@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Float64Option configures a Float64Pool created by NewFloat64Pool.
+type Float64Option func(*Float64Pool)
+
+// WithFloat64Allocator overrides the Allocator Float64Pool uses to grow its
+// backing buffer. The default allocates from the Go heap.
+func WithFloat64Allocator(a Allocator) Float64Option {
+	return func(fp *Float64Pool) {
+		fp.allocator = a
+	}
+}
+
+// WithFloat64Config bounds the pool's buffer growth and wires up metrics
+// collection, mirroring FloatPool's WithConfig. The default PoolConfig
+// imposes no bounds.
+func WithFloat64Config(cfg PoolConfig) Float64Option {
+	return func(fp *Float64Pool) {
+		fp.config = cfg
+	}
+}
+
+// Float64Pool is the value-slice counterpart to FloatPool: it leases
+// []float64 directly out of a single contiguous backing array, so callers
+// writing numeric results avoid the one-allocation-per-element cost of
+// FloatPool's []*float64 slots. This type is thread safe.
+type Float64Pool struct {
+	buf         []float64
+	bufs        [][]float64
+	book        allocBook
+	lock        *sync.Mutex
+	allocator   Allocator
+	config      PoolConfig
+	initialSize int
+	live        int
+	idleReturns int
+}
+
+// NewFloat64Pool creates a new float64 pool with a default size buffer.
+// The buffer will double size each time it's required to grow.
+func NewFloat64Pool(size int, opts ...Float64Option) *Float64Pool {
+	fp := &Float64Pool{
+		book:        newAllocBook(),
+		lock:        new(sync.Mutex),
+		allocator:   heapAllocator{},
+		initialSize: size,
+	}
+
+	for _, opt := range opts {
+		opt(fp)
+	}
+
+	fp.buf = fp.allocator.Alloc(size)
+	fp.bufs = append(fp.bufs, fp.buf)
+	recordPeakBufferSize(&fp.config, len(fp.buf))
+
+	return fp
+}
+
+// Make creates a new slice allocation from the pool and returns it.
+// Any slices created by the pool should be explicitly returned to
+// the pool once it is no longer used. Ensure any data that must persist
+// is copied before returned. Failure to return a slice can result in
+// leaks and unnecessary pooled allocations.
+//
+// If growing the buffer to satisfy this allocation would exceed
+// PoolConfig.MaxBufferSize, Make bypasses the pool and returns a plain
+// make([]float64, length) instead; Return is then a no-op for the
+// returned slice, since it was never leased from a buffer of fp's.
+func (fp *Float64Pool) Make(length int) []float64 {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Gets }, 1)
+
+	// find the next allocation location, resize buffer if necessary
+	next, ele, buffLength := fp.book.reserve(len(fp.buf), length)
+
+	if buffLength > len(fp.buf) {
+		if fp.config.MaxBufferSize > 0 && buffLength > fp.config.MaxBufferSize {
+			return make([]float64, length)
+		}
+
+		// The old buffer isn't freed here: callers may still be holding
+		// slices leased from it that haven't been Returned yet. Every
+		// buffer the pool has ever grown into is kept and freed together
+		// by Destroy.
+		newBuf := fp.allocator.Alloc(buffLength)
+		copy(newBuf, fp.buf)
+		fp.buf = newBuf
+		fp.bufs = append(fp.bufs, fp.buf)
+
+		metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Grows }, 1)
+		recordPeakBufferSize(&fp.config, len(fp.buf))
+	}
+
+	// create the slice from subset of buf
+	sl := fp.buf[next : next+length]
+
+	// record the allocation, advance search position
+	fp.book.record(next, length, fp.addressFor(sl), ele)
+
+	fp.live++
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.LiveAllocations }, 1)
+
+	return sl
+}
+
+// Return accepts a slice allocation that was created by calling Make on
+// this pool instance. Ensure any data that must persist from the returned
+// slice is copied. A lost Return (one that can't be matched to a live
+// allocation) is counted in PoolConfig.Metrics.Misses rather than reported
+// any other way; configure Metrics to observe leaks in production.
+//
+// A slice Make handed out after bypassing the pool (because it would have
+// exceeded PoolConfig.MaxBufferSize) is outside any of fp.bufs's address
+// ranges; Return detects that and is a no-op for it.
+func (fp *Float64Pool) Return(v []float64) {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	addr := fp.addressFor(v)
+	if !fp.addressInRange(addr) {
+		return
+	}
+
+	if _, ok := fp.book.release(addr); !ok {
+		metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Misses }, 1)
+		return
+	}
+
+	// zero out returned slice
+	fp.clear(v)
+
+	fp.live--
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.LiveAllocations }, -1)
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Puts }, 1)
+
+	fp.maybeShrink()
+}
+
+// addressInRange reports whether addr falls within any buffer the pool has
+// ever grown into.
+func (fp *Float64Pool) addressInRange(addr uintptr) bool {
+	for _, b := range fp.bufs {
+		if len(b) == 0 {
+			continue
+		}
+		start := fp.addressFor(b)
+		end := start + uintptr(len(b))*unsafe.Sizeof(b[0])
+		if addr >= start && addr < end {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeShrink resets the current buffer back down to its original size
+// once the pool has been fully drained MaxIdleAllocations times in a row,
+// so an oversized buffer from a past burst of allocations can be
+// reclaimed. Unlike growth, this is reached only when fp.live is 0, so no
+// buffer the pool has ever grown into can still have an outstanding lease;
+// every one of them is freed here rather than left for Destroy.
+func (fp *Float64Pool) maybeShrink() {
+	if fp.config.MaxIdleAllocations <= 0 || fp.live > 0 {
+		return
+	}
+
+	fp.idleReturns++
+	if fp.idleReturns < fp.config.MaxIdleAllocations {
+		return
+	}
+
+	fp.idleReturns = 0
+	if len(fp.buf) <= fp.initialSize {
+		return
+	}
+
+	for _, b := range fp.bufs {
+		fp.allocator.Free(b)
+	}
+
+	fp.buf = fp.allocator.Alloc(fp.initialSize)
+	fp.bufs = fp.bufs[:0]
+	fp.bufs = append(fp.bufs, fp.buf)
+	fp.book.reset()
+}
+
+// Destroy releases every buffer the pool has ever allocated via its
+// Allocator. The pool must not be used after calling Destroy.
+func (fp *Float64Pool) Destroy() {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	for _, b := range fp.bufs {
+		fp.allocator.Free(b)
+	}
+	fp.bufs = nil
+	fp.buf = nil
+}
+
+// zeros out indices of the slice parameter
+func (fp *Float64Pool) clear(v []float64) {
+	for i := range v {
+		v[i] = 0
+	}
+}
+
+// addressFor finds the address for the slice
+func (fp *Float64Pool) addressFor(v []float64) uintptr {
+	return uintptr(unsafe.Pointer(&v[0]))
+}
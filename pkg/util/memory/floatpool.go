@@ -1,33 +1,189 @@
 package memory
 
 import (
-	"container/list"
-	"fmt"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
+// Allocator backs the arena FloatPool carves NewValue results out of.
+// Implementations may return memory from the Go heap (the default, via
+// heapAllocator) or from elsewhere, such as the manual package's
+// C-allocator-backed implementation, to keep large buffers off the Go heap
+// and out of GC scans.
+type Allocator interface {
+	Alloc(n int) []float64
+	Free(v []float64)
+}
+
+// heapAllocator is the default Allocator, backed by ordinary Go slices.
+type heapAllocator struct{}
+
+func (heapAllocator) Alloc(n int) []float64 { return make([]float64, n) }
+func (heapAllocator) Free(v []float64)      {}
+
+// Option configures a FloatPool created by NewFloatPool.
+type Option func(*FloatPool)
+
+// WithAllocator overrides the Allocator FloatPool uses to back the arena
+// NewValue leases from. The default allocates from the Go heap.
+func WithAllocator(a Allocator) Option {
+	return func(fp *FloatPool) {
+		fp.allocator = a
+	}
+}
+
+// PoolConfig bounds a FloatPool's memory usage and exposes runtime metrics.
+// The zero value imposes no bounds and collects no metrics, matching
+// FloatPool's original unbounded behavior.
+type PoolConfig struct {
+	// MaxBufferSize caps how large the pool's backing buffer is allowed to
+	// grow, in elements. Once growing past it would be required, Make
+	// bypasses the pool and returns a plain make([]*float64, n) instead of
+	// doubling forever. Zero means unbounded.
+	MaxBufferSize int
+
+	// MaxIdleAllocations caps how many consecutive fully-drained Returns the
+	// pool tolerates before shrinking its backing buffer back down to its
+	// original size, releasing the rest for the Go heap to reclaim. Zero
+	// means the buffer is never shrunk.
+	MaxIdleAllocations int
+
+	// Metrics, if set, is updated as the pool is used.
+	Metrics *Metrics
+}
+
+// Metrics counts FloatPool or Float64Pool activity for observability. All
+// fields are updated with atomic operations and may be read concurrently
+// with pool use.
+type Metrics struct {
+	Gets            int64
+	Puts            int64
+	Grows           int64
+	Misses          int64
+	LiveAllocations int64
+	PeakBufferSize  int64
+}
+
+// WithConfig bounds the pool's buffer growth and wires up metrics
+// collection. The default PoolConfig imposes no bounds.
+func WithConfig(cfg PoolConfig) Option {
+	return func(fp *FloatPool) {
+		fp.config = cfg
+	}
+}
+
+// metricsAdd adds delta to the named Metrics counter, if cfg has metrics
+// configured. field is only dereferenced once cfg.Metrics is known non-nil.
+// Shared by FloatPool and Float64Pool.
+func metricsAdd(cfg *PoolConfig, field func(*Metrics) *int64, delta int64) {
+	m := cfg.Metrics
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(field(m), delta)
+}
+
+// recordPeakBufferSize updates cfg's Metrics peak buffer size if size is the
+// largest seen so far. Shared by FloatPool and Float64Pool.
+func recordPeakBufferSize(cfg *PoolConfig, size int) {
+	m := cfg.Metrics
+	if m == nil {
+		return
+	}
+
+	s := int64(size)
+	for {
+		peak := atomic.LoadInt64(&m.PeakBufferSize)
+		if s <= peak || atomic.CompareAndSwapInt64(&m.PeakBufferSize, peak, s) {
+			return
+		}
+	}
+}
+
 // FloatPool is a float64 buffer capable of leasing out slices for temporary use.
 // This can reduce total heap allocations for critcial code paths. This type is
 // thread safe.
+//
+// Legacy: FloatPool leases []*float64, so each element still needs a
+// *float64 of its own (via NewValue or new(float64)) before it's useful.
+// Prefer Float64Pool for new call sites, which leases []float64 directly
+// out of a contiguous backing array and avoids that per-element allocation.
+// FloatPool remains for callers that need individually-addressable pointers
+// into the leased slice.
 type FloatPool struct {
 	buf         []*float64
-	allocations AllocationList
-	pos         int
-	start       *list.Element
+	book        allocBook
 	lock        *sync.Mutex
+	allocator   Allocator
+	arena       []float64
+	arenaPos    int
+	arenas      [][]float64
+	config      PoolConfig
+	initialSize int
+	live        int
+	idleReturns int
 }
 
 // Create a new float pool with a default size buffer. The buffer will double size
 // each time it's required to grow.
-func NewFloatPool(size int) *FloatPool {
-	return &FloatPool{
+func NewFloatPool(size int, opts ...Option) *FloatPool {
+	fp := &FloatPool{
 		buf:         make([]*float64, size),
-		pos:         0,
-		start:       nil,
-		allocations: NewAllocationList(),
+		book:        newAllocBook(),
 		lock:        new(sync.Mutex),
+		allocator:   heapAllocator{},
+		initialSize: size,
+	}
+
+	for _, opt := range opts {
+		opt(fp)
+	}
+
+	recordPeakBufferSize(&fp.config, len(fp.buf))
+
+	return fp
+}
+
+// NewValue returns a pointer to a fresh float64 carved out of the pool's
+// arena, which is allocated via the pool's Allocator (the Go heap by
+// default). Callers populating slots returned by Make can use this instead
+// of `new(float64)` to keep those values out of the Go heap when the pool
+// was constructed with an off-heap Allocator such as manual.Allocator.
+//
+// Pointers returned by NewValue stay valid for the life of the pool: once
+// an arena is exhausted, a new one is allocated and kept alongside it
+// rather than replacing it, so refilling the arena never invalidates a
+// pointer a caller is still holding. All arenas are freed together by
+// Destroy.
+func (fp *FloatPool) NewValue() *float64 {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	if fp.arenaPos >= len(fp.arena) {
+		fp.arena = fp.allocator.Alloc(len(fp.buf))
+		fp.arenas = append(fp.arenas, fp.arena)
+		fp.arenaPos = 0
 	}
+
+	v := &fp.arena[fp.arenaPos]
+	fp.arenaPos++
+	return v
+}
+
+// Destroy releases all memory the pool obtained from its Allocator for
+// NewValue, across every arena it has ever allocated. The pool must not be
+// used after calling Destroy.
+func (fp *FloatPool) Destroy() {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	for _, a := range fp.arenas {
+		fp.allocator.Free(a)
+	}
+	fp.arenas = nil
+	fp.arena = nil
+	fp.arenaPos = 0
 }
 
 // Make creates a new slice allocation from the pool and returns it.
@@ -35,64 +191,112 @@ func NewFloatPool(size int) *FloatPool {
 // the pool once it is no longer used. Ensure any data that must persist
 // is copied before returned. Failure to return a slice can result in
 // leaks and unnecessary pooled allocations.
+//
+// If growing the buffer to satisfy this allocation would exceed
+// PoolConfig.MaxBufferSize, Make bypasses the pool and returns a plain
+// make([]*float64, length) instead; Return is then a no-op for the
+// returned slice, since it was never leased from fp.buf.
 func (fp *FloatPool) Make(length int) []*float64 {
 	fp.lock.Lock()
 	defer fp.lock.Unlock()
 
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Gets }, 1)
+
 	// find the next allocation location, resize buffer if necessary
-	next, ele := fp.allocations.Next(fp.start, fp.pos, len(fp.buf), length)
-
-	// if the next allocation location + length is larger than the buffer,
-	// grow the buffer
-	buffLength := len(fp.buf)
-	if next+length >= buffLength {
-		for next+length >= buffLength {
-			buffLength = buffLength * 2
+	next, ele, buffLength := fp.book.reserve(len(fp.buf), length)
+
+	if buffLength > len(fp.buf) {
+		if fp.config.MaxBufferSize > 0 && buffLength > fp.config.MaxBufferSize {
+			return make([]*float64, length)
 		}
 
 		newBuf := make([]*float64, buffLength)
 		copy(newBuf, fp.buf)
 		fp.buf = newBuf
+
+		metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Grows }, 1)
+		recordPeakBufferSize(&fp.config, len(fp.buf))
 	}
 
 	// create the slice from subset of buf
 	sl := fp.buf[next : next+length]
 
-	// insert allocation record, advance search position
-	ele = fp.allocations.InsertBefore(&Allocation{
-		Offset: next,
-		Size:   length,
-		Addr:   fp.addressFor(sl),
-	}, ele)
+	// record the allocation, advance search position
+	fp.book.record(next, length, fp.addressFor(sl), ele)
 
-	fp.pos = next + length + 1
-	fp.start = ele
+	fp.live++
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.LiveAllocations }, 1)
 
 	return sl
 }
 
 // Return accepts a slice allocation that was created by calling Make on
 // this pool instance. Ensure any data that must persist from the returned
-// slice is copied. Failure to return a slice can result in leaks and
-// unnecessary additional pooled allocations.
+// slice is copied. A lost Return (one that can't be matched to a live
+// allocation) is counted in PoolConfig.Metrics.Misses rather than reported
+// any other way; configure Metrics to observe leaks in production.
+//
+// A slice Make handed out after bypassing the pool (because it would have
+// exceeded PoolConfig.MaxBufferSize) matches no live allocation and is
+// outside fp.buf's address range; Return detects that and is a no-op for
+// it. An unmatched slice outside fp.buf's current range is therefore not
+// counted as a miss.
 func (fp *FloatPool) Return(v []*float64) {
 	fp.lock.Lock()
 	defer fp.lock.Unlock()
 
-	removed, next := fp.allocations.Remove(fp.addressFor(v))
-	if removed == nil {
-		fmt.Printf("Error: Failed to locate allocated slice\n")
+	// Slices leased before a growth point into an older buffer, so the
+	// allocation is looked up by address before the range check.
+	addr := fp.addressFor(v)
+	if _, ok := fp.book.release(addr); !ok {
+		if fp.addressInRange(addr) {
+			metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Misses }, 1)
+		}
 		return
 	}
 
-	// set the search start at the lowest returned
-	if removed.Offset < fp.pos {
-		fp.pos = removed.Offset
-		fp.start = next
-	}
-
 	// nil out returned slice
 	fp.clear(v)
+
+	fp.live--
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.LiveAllocations }, -1)
+	metricsAdd(&fp.config, func(m *Metrics) *int64 { return &m.Puts }, 1)
+
+	fp.maybeShrink()
+}
+
+// addressInRange reports whether addr falls within fp.buf's current
+// backing array.
+func (fp *FloatPool) addressInRange(addr uintptr) bool {
+	if len(fp.buf) == 0 {
+		return false
+	}
+
+	start := fp.addressFor(fp.buf)
+	end := start + uintptr(len(fp.buf))*unsafe.Sizeof(fp.buf[0])
+	return addr >= start && addr < end
+}
+
+// maybeShrink resets the buffer back down to its original size once the
+// pool has been fully drained MaxIdleAllocations times in a row, so an
+// oversized buffer from a past burst of allocations can be reclaimed.
+func (fp *FloatPool) maybeShrink() {
+	if fp.config.MaxIdleAllocations <= 0 || fp.live > 0 {
+		return
+	}
+
+	fp.idleReturns++
+	if fp.idleReturns < fp.config.MaxIdleAllocations {
+		return
+	}
+
+	fp.idleReturns = 0
+	if len(fp.buf) <= fp.initialSize {
+		return
+	}
+
+	fp.buf = make([]*float64, fp.initialSize)
+	fp.book.reset()
 }
 
 // nils out indices of the slice parameter
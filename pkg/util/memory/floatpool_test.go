@@ -0,0 +1,126 @@
+package memory
+
+import "testing"
+
+// countingAllocator tracks every slice it has handed out via Alloc and
+// every one handed back via Free, so tests can assert nothing leaks.
+type countingAllocator struct {
+	allocs int
+	frees  int
+	live   map[*float64]bool
+}
+
+func newCountingAllocator() *countingAllocator {
+	return &countingAllocator{live: make(map[*float64]bool)}
+}
+
+func (a *countingAllocator) Alloc(n int) []float64 {
+	a.allocs++
+	v := make([]float64, n)
+	if n > 0 {
+		a.live[&v[0]] = true
+	}
+	return v
+}
+
+func (a *countingAllocator) Free(v []float64) {
+	if len(v) == 0 {
+		return
+	}
+	a.frees++
+	delete(a.live, &v[0])
+}
+
+func TestFloatPoolDestroyFreesEveryArena(t *testing.T) {
+	alloc := newCountingAllocator()
+	fp := NewFloatPool(2, WithAllocator(alloc))
+
+	// Exhaust several arenas' worth of values to force multiple refills.
+	for i := 0; i < 5; i++ {
+		fp.NewValue()
+	}
+
+	if alloc.allocs < 2 {
+		t.Fatalf("expected at least 2 Alloc calls across refills, got %d", alloc.allocs)
+	}
+	if alloc.frees != 0 {
+		t.Fatalf("arenas must not be freed while still referenced, got %d frees before Destroy", alloc.frees)
+	}
+
+	fp.Destroy()
+
+	if alloc.frees != alloc.allocs {
+		t.Fatalf("Destroy freed %d of %d allocated arenas", alloc.frees, alloc.allocs)
+	}
+	if len(alloc.live) != 0 {
+		t.Fatalf("expected no live arenas after Destroy, got %d", len(alloc.live))
+	}
+}
+
+func TestFloatPoolMaxBufferSizeBypassesPool(t *testing.T) {
+	fp := NewFloatPool(2, WithConfig(PoolConfig{MaxBufferSize: 2}))
+
+	a := fp.Make(2)
+	if len(a) != 2 {
+		t.Fatalf("len(a) = %d, want 2", len(a))
+	}
+
+	// This allocation needs the buffer to grow past MaxBufferSize, so it
+	// should bypass the pool rather than growing forever.
+	b := fp.Make(2)
+	if len(b) != 2 {
+		t.Fatalf("len(b) = %d, want 2", len(b))
+	}
+
+	// Returning a bypassed slice must be a harmless no-op, not corrupt the
+	// pool's own bookkeeping.
+	fp.Return(b)
+
+	c := fp.Make(1)
+	if len(c) != 1 {
+		t.Fatalf("len(c) = %d, want 1", len(c))
+	}
+}
+
+func TestFloatPoolMaxIdleAllocationsShrinksBuffer(t *testing.T) {
+	metrics := &Metrics{}
+	fp := NewFloatPool(2, WithConfig(PoolConfig{MaxIdleAllocations: 1, Metrics: metrics}))
+
+	// Force growth past the initial size. With MaxIdleAllocations set to 1,
+	// the Return below will itself fully drain the pool and immediately
+	// trip the shrink, so the grown size must be checked beforehand.
+	a := fp.Make(4)
+	if got := len(fp.buf); got <= 2 {
+		t.Fatalf("expected buffer to have grown past its initial size, got %d", got)
+	}
+	fp.Return(a)
+
+	if got := len(fp.buf); got != 2 {
+		t.Fatalf("len(fp.buf) = %d, want 2 (initial size) after idle shrink", got)
+	}
+
+	// A subsequent fully-drained Return cycle should shrink again (it's a
+	// no-op here since the buffer is already at its initial size).
+	b := fp.Make(1)
+	fp.Return(b)
+
+	if got := len(fp.buf); got != 2 {
+		t.Fatalf("len(fp.buf) = %d, want 2 (initial size) after idle shrink", got)
+	}
+	if metrics.Grows == 0 {
+		t.Fatalf("expected Grows metric to be recorded")
+	}
+}
+
+func TestFloatPoolReturnMissIsCountedNotPrinted(t *testing.T) {
+	metrics := &Metrics{}
+	fp := NewFloatPool(4, WithConfig(PoolConfig{Metrics: metrics}))
+
+	a := fp.Make(2)
+	fp.Return(a)
+	fp.Return(a) // double-return: the address is no longer a live allocation
+
+	if metrics.Misses != 1 {
+		t.Fatalf("metrics.Misses = %d, want 1", metrics.Misses)
+	}
+}
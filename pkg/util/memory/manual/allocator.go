@@ -0,0 +1,38 @@
+// Package manual provides an Allocator backed by the C heap, for use by
+// memory.FloatPool (via memory.WithAllocator) when GC scan cost on large
+// buffers dominates a long-running calculation.
+package manual
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import "unsafe"
+
+// Allocator allocates []float64 buffers from the C heap via malloc,
+// bypassing the Go heap and garbage collector entirely. The zero value is
+// ready to use. Memory obtained from Alloc must be released with Free
+// exactly once; it is not collected by the Go garbage collector.
+type Allocator struct{}
+
+// Alloc returns a slice of n float64s backed by C-allocated memory.
+func (Allocator) Alloc(n int) []float64 {
+	if n == 0 {
+		return nil
+	}
+
+	size := C.size_t(n) * C.size_t(unsafe.Sizeof(float64(0)))
+	ptr := C.malloc(size)
+	return unsafe.Slice((*float64)(ptr), n)
+}
+
+// Free releases a slice previously returned by Alloc. Passing a slice not
+// obtained from Alloc is undefined behavior.
+func (Allocator) Free(v []float64) {
+	if len(v) == 0 {
+		return
+	}
+
+	C.free(unsafe.Pointer(&v[0]))
+}
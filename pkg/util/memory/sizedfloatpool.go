@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"math/bits"
+	"sync"
+	"unsafe"
+)
+
+// maxPoolBucketShift bounds the largest bucket size SizedFloatPool will
+// pool, expressed as a power of two. Requests for slices larger than
+// 2^maxPoolBucketShift bypass the pool and are allocated directly.
+const maxPoolBucketShift = 20
+
+// SizedFloatPool is a float64 slice pool backed by a fixed set of
+// size-classed sync.Pool buckets, one per power-of-two capacity from 1 up
+// to 2^maxPoolBucketShift. Unlike FloatPool, which walks a single shared
+// arena under a mutex, SizedFloatPool hands out and reclaims buffers
+// independently per bucket, so concurrent callers rarely contend with one
+// another and idle buckets can be reclaimed by the runtime under GC
+// pressure. This type is thread safe.
+//
+// SizedFloatPool is the sync.Pool-based replacement for FloatPool's
+// mutex-and-linear-scan Return path: callers that don't need FloatPool's
+// contiguous-arena offsets (e.g. individually addressable *float64 slots)
+// should use SizedFloatPool directly rather than FloatPool.Return. FloatPool
+// itself keeps its AllocationList-backed design, since its Return semantics
+// (reclaiming a specific offset range in one shared arena) don't map onto
+// independent size-classed buckets.
+type SizedFloatPool struct {
+	buckets [maxPoolBucketShift + 1]sync.Pool
+}
+
+// NewSizedFloatPool creates a new size-classed float pool.
+func NewSizedFloatPool() *SizedFloatPool {
+	sp := &SizedFloatPool{}
+	for i := range sp.buckets {
+		size := 1 << uint(i)
+		sp.buckets[i].New = func() interface{} {
+			return unsafe.Pointer(&make([]float64, size)[0])
+		}
+	}
+	return sp
+}
+
+// bucketShift returns the smallest k such that 1<<k >= n.
+func bucketShift(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// Make returns a slice of length n backed by a buffer leased from the
+// bucket sized to the smallest power of two >= n. Slices larger than
+// 2^maxPoolBucketShift bypass the pool entirely. Any slice returned by
+// Make should be handed back via Return once it is no longer in use.
+//
+// Buckets store the head of the backing array as an unsafe.Pointer rather
+// than a []float64, and Make reconstructs the slice header here from the
+// known bucket capacity. A []float64 boxed into the sync.Pool's
+// interface{} value would itself require a heap allocation on every Put;
+// a bare pointer fits in the interface's data word and doesn't.
+func (sp *SizedFloatPool) Make(n int) []float64 {
+	shift := bucketShift(n)
+	if shift > maxPoolBucketShift {
+		return make([]float64, n)
+	}
+
+	ptr := sp.buckets[shift].Get().(unsafe.Pointer)
+	full := unsafe.Slice((*float64)(ptr), 1<<uint(shift))
+	return full[:n]
+}
+
+// Return hands a slice created by Make back to its bucket. Slices whose
+// capacity doesn't match a bucket size class exactly (for example ones
+// allocated outside Make because they exceeded 2^maxPoolBucketShift) are
+// dropped rather than pooled, so two callers never end up sharing the same
+// backing array.
+func (sp *SizedFloatPool) Return(v []float64) {
+	c := cap(v)
+	if c == 0 {
+		return
+	}
+
+	shift := bucketShift(c)
+	if shift > maxPoolBucketShift || 1<<uint(shift) != c {
+		return
+	}
+
+	full := v[:c]
+	sp.buckets[shift].Put(unsafe.Pointer(&full[0]))
+}
@@ -0,0 +1,96 @@
+package memory
+
+import "testing"
+
+// TestSizedFloatPoolRoundTrip checks that Return/Make round-trips a slice's
+// length and capacity. It does not assert anything about the slice's
+// contents: sync.Pool makes no retention guarantee, and buckets may be
+// reclaimed by the GC between Put and Get, so a Make following a Return can
+// legitimately come back zeroed rather than holding the old data.
+func TestSizedFloatPoolRoundTrip(t *testing.T) {
+	sp := NewSizedFloatPool()
+
+	v := sp.Make(5)
+	if len(v) != 5 {
+		t.Fatalf("len(v) = %d, want 5", len(v))
+	}
+	wantCap := cap(v)
+
+	sp.Return(v)
+
+	v2 := sp.Make(5)
+	if len(v2) != 5 {
+		t.Fatalf("len(v2) = %d, want 5", len(v2))
+	}
+	if cap(v2) != wantCap {
+		t.Fatalf("cap(v2) = %d, want %d", cap(v2), wantCap)
+	}
+}
+
+func TestSizedFloatPoolBucketShift(t *testing.T) {
+	cases := []struct {
+		n    int
+		want int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 1},
+		{3, 2},
+		{4, 2},
+		{5, 3},
+		{1024, 10},
+		{1025, 11},
+	}
+
+	for _, c := range cases {
+		if got := bucketShift(c.n); got != c.want {
+			t.Errorf("bucketShift(%d) = %d, want %d", c.n, got, c.want)
+		}
+	}
+}
+
+func TestSizedFloatPoolMakeExactCapacity(t *testing.T) {
+	sp := NewSizedFloatPool()
+
+	for _, n := range []int{1, 2, 3, 4, 5, 16, 17, 1000} {
+		v := sp.Make(n)
+		if len(v) != n {
+			t.Fatalf("Make(%d): len = %d, want %d", n, len(v), n)
+		}
+
+		wantCap := 1 << uint(bucketShift(n))
+		if cap(v) != wantCap {
+			t.Fatalf("Make(%d): cap = %d, want %d", n, cap(v), wantCap)
+		}
+
+		sp.Return(v)
+	}
+}
+
+func TestSizedFloatPoolReturnDropsMismatchedCapacity(t *testing.T) {
+	sp := NewSizedFloatPool()
+
+	// A slice whose capacity isn't an exact bucket size (e.g. grown past
+	// the pool's knowledge via append) must be dropped, not pooled: Put-ing
+	// it under a bucket whose Get reconstructs a larger slice than this
+	// one's actual backing array would corrupt memory past its end.
+	odd := make([]float64, 3, 7)
+	sp.Return(odd) // must not panic
+
+	v := sp.Make(3)
+	if len(v) != 3 {
+		t.Fatalf("len(v) = %d, want 3", len(v))
+	}
+}
+
+func TestSizedFloatPoolOversizeBypassesPool(t *testing.T) {
+	sp := NewSizedFloatPool()
+
+	n := 1 << (maxPoolBucketShift + 1)
+	v := sp.Make(n)
+	if len(v) != n {
+		t.Fatalf("len(v) = %d, want %d", len(v), n)
+	}
+
+	sp.Return(v) // must not panic; should be a no-op
+}